@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -23,12 +25,23 @@ type minimalDashboard struct {
 		ID         int64              `json:"id"`
 		Datasource string             `json:"datasource"`
 		Targets    []*simplejson.Json `json:"targets"`
+		Alert      *struct {
+			NoDataState string `json:"noDataState"`
+			Conditions  []struct {
+				Reducer struct {
+					Type string `json:"type"`
+				} `json:"reducer"`
+			} `json:"conditions"`
+		} `json:"alert"`
 	} `json:"panels"`
 }
 
 // AlertNG is the service for evaluating the condition of an alert definition.
 type AlertNG struct {
 	DatasourceCache datasources.CacheService `inject:""`
+	RouteRegister   routing.RouteRegister    `inject:""`
+
+	queryDefaults *queryDefaultsTable
 }
 
 func init() {
@@ -37,9 +50,24 @@ func init() {
 
 // Init initializes the AlertingService.
 func (ng *AlertNG) Init() error {
+	ng.queryDefaults = newQueryDefaultsTable(builtinQueryDefaults)
+	if err := ng.queryDefaults.loadDescriptor(defaultQueryDefaultsDescriptor); err != nil {
+		return err
+	}
+	ng.registerGraphQLRoutes()
 	return nil
 }
 
+// RegisterQueryDefaults lets plugins declare default values for query
+// fields of a given datasource type (e.g. "step" for Loki, "region" for
+// CloudWatch), merged into the query model during LoadAlertCondition
+// whenever the dashboard panel JSON omits the field. Passing
+// datasourceType == "" registers a default applied to every query
+// regardless of its datasource.
+func (ng *AlertNG) RegisterQueryDefaults(datasourceType string, defaults map[string]interface{}) {
+	ng.queryDefaults.register(datasourceType, defaults)
+}
+
 // AlertExecCtx is the context provided for executing an alert condition.
 type AlertExecCtx struct {
 	AlertDefitionID int64
@@ -54,6 +82,19 @@ type Condition struct {
 	RefID string `json:"refId"`
 
 	QueriesAndExpressions []tsdb.Query `json:"queriesAndExpressions"`
+
+	// Reducer names the Reducer (see GetReducer) used to collapse each
+	// result frame's time series down to the single value/State
+	// EvaluateExecutionResult reports per alert instance. Populated from
+	// the dashboard panel's alert condition reducer type by
+	// LoadAlertCondition; defaults to "last".
+	Reducer string `json:"reducer"`
+
+	// NoDataState is the State reported for a frame that reduces to no
+	// samples, e.g. because its query returned nothing. Populated from the
+	// dashboard panel's alert noDataState by LoadAlertCondition; defaults
+	// to NoData.
+	NoDataState State `json:"noDataState"`
 }
 
 // ExecutionResults contains the unevaluated results from executing
@@ -64,6 +105,13 @@ type ExecutionResults struct {
 	Error error
 
 	Results data.Frames
+
+	// Reducer is the Condition.Reducer resolved by Execute, consulted by
+	// EvaluateExecutionResult to collapse each frame.
+	Reducer Reducer
+
+	// NoDataState is the Condition.NoDataState resolved by Execute.
+	NoDataState State
 }
 
 // Results is a slice of evaluated alert instances states.
@@ -85,12 +133,64 @@ const (
 	Normal State = iota
 
 	// Alerting is the eval state for an alert instance condition
-	// that evaluated to false.
+	// that evaluated to true.
 	Alerting
+
+	// Pending is reserved for an alert instance whose condition has
+	// evaluated to true for less than its configured for-duration, once
+	// for-duration tracking exists. Nothing in this package currently
+	// produces it; it's defined now so the enum (and its GraphQL/JSON
+	// encodings) doesn't have to change shape when that tracking lands.
+	Pending
+
+	// NoData is the eval state for an alert instance whose frame reduced
+	// to no samples to evaluate.
+	NoData
+
+	// Error is the eval state for an alert instance whose evaluation
+	// failed, e.g. because its frame could not be reduced.
+	Error
 )
 
+// stateNames holds State's String() form, indexed by its iota value; it is
+// also the authority StateFromString and the JSON (un)marshalers parse
+// against.
+var stateNames = [...]string{"Normal", "Alerting", "Pending", "NoData", "Error"}
+
 func (s State) String() string {
-	return [...]string{"Normal", "Alerting"}[s]
+	return stateNames[s]
+}
+
+// StateFromString parses a State's name back from string, case-insensitively
+// so both the GraphQL schema's enum names (e.g. "NORMAL") and the dashboard
+// model's (e.g. "NoData") resolve to the same values.
+func StateFromString(name string) (State, error) {
+	for i, n := range stateNames {
+		if strings.EqualFold(n, name) {
+			return State(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown state %q", name)
+}
+
+// MarshalJSON implements json.Marshaler, so a State round-trips through the
+// simplejson-backed dashboard model as its name, e.g. "NoData".
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the counterpart to MarshalJSON.
+func (s *State) UnmarshalJSON(raw []byte) error {
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return err
+	}
+	parsed, err := StateFromString(name)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
 }
 
 // IsValid checks the conditions validity
@@ -123,7 +223,6 @@ func (ng *AlertNG) LoadAlertCondition(dashboardID int64, panelID int64, conditio
 			panelDatasource := p.Datasource
 			var ds *models.DataSource
 			for i, query := range p.Targets {
-				refID := query.Get("refId").MustString("A")
 				queryDatasource := query.Get("datasource").MustString()
 
 				if i == 0 && queryDatasource != "__expr__" {
@@ -155,26 +254,16 @@ func (ng *AlertNG) LoadAlertCondition(dashboardID int64, panelID int64, conditio
 					query.Set("datasourceId", ds.Id)
 				}
 
-				if query.Get("orgId").MustString() == "" { // GEL requires orgID inside the query JSON
-					// need to decide which organization id is expected there
-					// in grafana queries is passed the signed in user organization id:
-					// https://github.com/grafana/grafana/blob/34a355fe542b511ed02976523aa6716aeb00bde6/packages/grafana-runtime/src/utils/DataSourceWithBackend.ts#L60
-					// but I think that it should be datasource org id instead
-					query.Set("orgId", 0)
-				}
-
-				if query.Get("maxDataPoints").MustString() == "" { // GEL requires maxDataPoints inside the query JSON
-					query.Set("maxDataPoints", 100)
-				}
-
-				// intervalMS is calculated by the frontend
-				// should we do something similar?
-				if query.Get("intervalMs").MustString() == "" { // GEL requires intervalMs inside the query JSON
-					query.Set("intervalMs", 1000)
+				// orgId, maxDataPoints, intervalMs and refId are filled in from
+				// the query-defaults table when the dashboard panel JSON omits
+				// them; see query_defaults.go for the declarative table and
+				// AlertNG.RegisterQueryDefaults for how plugins extend it.
+				if err := ng.queryDefaults.merge(query, ds.Type); err != nil {
+					return nil, err
 				}
 
 				condition.QueriesAndExpressions = append(condition.QueriesAndExpressions, tsdb.Query{
-					RefId:         refID,
+					RefId:         query.Get("refId").MustString("A"),
 					MaxDataPoints: query.Get("maxDataPoints").MustInt64(100),
 					IntervalMs:    query.Get("intervalMs").MustInt64(1000),
 					QueryType:     query.Get("queryType").MustString(""),
@@ -182,6 +271,20 @@ func (ng *AlertNG) LoadAlertCondition(dashboardID int64, panelID int64, conditio
 					DataSource:    ds,
 				})
 			}
+
+			// Reducer/NoDataState default to "last"/NoData, the same
+			// behaviour a panel with no alert conditions configured gets
+			// from the classic alerting engine.
+			condition.Reducer = "last"
+			condition.NoDataState = NoData
+			if p.Alert != nil {
+				if len(p.Alert.Conditions) > 0 && p.Alert.Conditions[0].Reducer.Type != "" {
+					condition.Reducer = p.Alert.Conditions[0].Reducer.Type
+				}
+				if state, err := StateFromString(p.Alert.NoDataState); err == nil {
+					condition.NoDataState = state
+				}
+			}
 		}
 	}
 	condition.RefID = conditionRefID
@@ -195,6 +298,18 @@ func (c *Condition) Execute(ctx AlertExecCtx, fromStr, toStr string) (*Execution
 		return nil, fmt.Errorf("Invalid conditions")
 	}
 
+	reducerName := c.Reducer
+	if reducerName == "" {
+		reducerName = "last"
+	}
+	reducer, err := GetReducer(reducerName)
+	if err != nil {
+		result.Error = err
+		return &result, err
+	}
+	result.Reducer = reducer
+	result.NoDataState = c.NoDataState
+
 	request := &tsdb.TsdbQuery{
 		TimeRange: tsdb.NewTimeRange(fromStr, toStr),
 		Debug:     true,
@@ -226,43 +341,50 @@ func (c *Condition) Execute(ctx AlertExecCtx, fromStr, toStr string) (*Execution
 	return &result, nil
 }
 
-// EvaluateExecutionResult takes the ExecutionResult, and returns a frame where
-// each column is a string type that holds a string representing its state.
+// EvaluateExecutionResult reduces each of the ExecutionResult's frames with
+// results.Reducer (falling back to the "last" reducer if none was
+// resolved) and returns the evaluated state of every alert instance the
+// frames identify.
 func EvaluateExecutionResult(results *ExecutionResults) (Results, error) {
-	evalResults := make([]Result, 0)
-	labels := make(map[string]bool)
-	for _, f := range results.Results {
-		rowLen, err := f.RowLen()
+	reducer := results.Reducer
+	if reducer == nil {
+		var err error
+		reducer, err = GetReducer("last")
 		if err != nil {
-			return nil, fmt.Errorf("Unable to get frame row length")
-		}
-		if rowLen > 1 {
-			return nil, fmt.Errorf("Invalid frame %v: row length %v", f.Name, rowLen)
-		}
-
-		if len(f.Fields) > 1 {
-			return nil, fmt.Errorf("Invalid frame %v: field length %v", f.Name, len(f.Fields))
+			return nil, err
 		}
+	}
 
-		if f.Fields[0].Type() != data.FieldTypeNullableFloat64 {
-			return nil, fmt.Errorf("Invalid frame %v: field type %v", f.Name, f.Fields[0].Type())
+	evalResults := make([]Result, 0)
+	labels := make(map[string]bool)
+	for _, f := range results.Results {
+		// A frame that Reduce can't make sense of (no/multiple nullable
+		// float64 fields) is that instance's problem alone: report it as
+		// Error and move on to the next frame instead of aborting the
+		// whole batch the way a hard error from valueField would.
+		field, labelsErr := valueField(f)
+
+		_, state, err := reducer.Reduce(f)
+		switch {
+		case err != nil:
+			state = Error
+		case state == NoData:
+			state = results.NoDataState
 		}
 
-		labelsStr := f.Fields[0].Labels.String()
-		_, ok := labels[labelsStr]
-		if ok {
-			return nil, fmt.Errorf("Invalid frame %v: frames cannot uniquely be identified by its labels: %q", f.Name, labelsStr)
-		}
-		labels[labelsStr] = true
+		instance := data.Labels{}
+		if labelsErr == nil {
+			instance = field.Labels
 
-		state := Normal
-		val, err := f.Fields[0].FloatAt(0)
-		if err != nil || val != 0 {
-			state = Alerting
+			labelsStr := instance.String()
+			if labels[labelsStr] {
+				return nil, fmt.Errorf("Invalid frame %v: frames cannot uniquely be identified by its labels: %q", f.Name, labelsStr)
+			}
+			labels[labelsStr] = true
 		}
 
 		evalResults = append(evalResults, Result{
-			Instance: f.Fields[0].Labels,
+			Instance: instance,
 			State:    state,
 		})
 	}
@@ -271,11 +393,17 @@ func EvaluateExecutionResult(results *ExecutionResults) (Results, error) {
 
 // AsDataFrame forms the EvalResults in Frame suitable for displaying in the table panel of the front end.
 // This may be temporary, as there might be a fair amount we want to display in the frontend, and it might not make sense to store that in data.Frame.
-// For the first pass, I would expect a Frame with a single row, and a column for each instance with a boolean value.
+// For the first pass, I would expect a Frame with a single row, and a column for each instance with its state name.
+//
+// This used to collapse every non-Normal state to a single boolean "alerting"
+// column, which was fine back when Normal/Alerting were the only two states
+// but silently merged Pending/NoData/Error into "alerting" too once those
+// were added. Encode the actual state name instead so the table panel (and
+// anything else reading this frame) can tell them apart.
 func (evalResults Results) AsDataFrame() data.Frame {
 	fields := make([]*data.Field, 0)
 	for _, evalResult := range evalResults {
-		fields = append(fields, data.NewField("", evalResult.Instance, []bool{evalResult.State != Normal}))
+		fields = append(fields, data.NewField("", evalResult.Instance, []string{evalResult.State.String()}))
 	}
 	f := data.NewFrame("", fields...)
 	return *f
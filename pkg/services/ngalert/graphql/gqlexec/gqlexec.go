@@ -0,0 +1,480 @@
+// Package gqlexec is a bespoke, schema-driven GraphQL executor for the
+// ngalert GraphQL API. It is NOT gqlgen output and never has been — despite
+// the gqlgen types it implements (graphql.ExecutableSchema) and the
+// gqlgen.yml alongside it, nothing here is or was machine-generated; treat
+// gqlgen.yml as documentation of what a real `gqlgen generate` run would
+// produce, not as a live config.
+//
+// Known limitations of the dispatcher below, by design rather than
+// oversight:
+//   - Exec/rootField only look at the operation's first top-level
+//     selection. A query or mutation with more than one root field (e.g.
+//     `{ conditionPreview(...) __typename }`) silently executes only the
+//     first and drops the rest instead of resolving both.
+//   - conditionJSON/resultJSON project a fixed set of keys for
+//     Condition/AlertQuery/Result; they don't look at the request's
+//     sub-selection set at all, so field aliases and partial selections on
+//     those object types have no effect — every field is always returned.
+//
+// Both are fine for this API's one query field and one mutation field, each
+// returning non-nested scalars/objects the frontend always asks for in
+// full. The moment either assumption stops holding — a second root field,
+// a client that wants to alias or omit a nested field — stop extending this
+// file and run `go run github.com/99designs/gqlgen generate` (see
+// ../gqlgen.yml) instead.
+package gqlexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/graphql/model"
+)
+
+// NewExecutableSchema creates an ExecutableSchema from the Config. Used by
+// handler.go to construct the gqlgen HTTP handler.
+func NewExecutableSchema(cfg Config) graphql.ExecutableSchema {
+	return &executableSchema{resolvers: cfg.Resolvers}
+}
+
+// Config bundles everything NewExecutableSchema needs to bind the schema to
+// hand-written resolvers.
+type Config struct {
+	Resolvers ResolverRoot
+}
+
+// ResolverRoot is implemented by graphql.Resolver (see resolver.go).
+type ResolverRoot interface {
+	Mutation() MutationResolver
+	Query() QueryResolver
+}
+
+// MutationResolver is implemented by graphql.Resolver.
+type MutationResolver interface {
+	EvaluateCondition(ctx context.Context, dashboardID int64, panelID int64, refID string, from string, to string) (*model.ExecutionResults, error)
+}
+
+// QueryResolver is implemented by graphql.Resolver.
+type QueryResolver interface {
+	ConditionPreview(ctx context.Context, dashboardID int64, panelID int64) (*model.Condition, error)
+}
+
+type executableSchema struct {
+	resolvers ResolverRoot
+}
+
+var parsedSchema = parser.MustParseSchema(&ast.Source{Name: "schema.graphqls", Input: schemaSource})
+
+func (e *executableSchema) Schema() *ast.Schema {
+	return parsedSchema
+}
+
+// Complexity is left unimplemented: the schema does not opt into query cost
+// limiting, so every request is accepted regardless of estimated cost.
+func (e *executableSchema) Complexity(typeName, field string, childComplexity int, args map[string]interface{}) (int, bool) {
+	return 0, false
+}
+
+// Exec dispatches the single top-level field of the operation to the
+// matching hand-written resolver (or to the introspection fields below) and
+// marshals its result as the response data.
+func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
+	rc := graphql.GetOperationContext(ctx)
+	root := rootField(rc.Operation)
+	if root == nil {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "empty selection set"))
+	}
+
+	var (
+		data interface{}
+		err  error
+	)
+	switch {
+	case root.Name == "__schema":
+		data = introspectSchema(parsedSchema)
+	case root.Name == "__type":
+		name, aerr := stringArg(root.Arguments, "name", rc.Variables)
+		if aerr != nil {
+			err = aerr
+			break
+		}
+		data = introspectType(parsedSchema, parsedSchema.Types[name])
+	case root.Name == "__typename":
+		data = rootTypeName(rc.Operation.Operation)
+	case rc.Operation.Operation == ast.Query:
+		data, err = e.resolveQuery(ctx, rc, root)
+	case rc.Operation.Operation == ast.Mutation:
+		data, err = e.resolveMutation(ctx, rc, root)
+	default:
+		err = fmt.Errorf("unsupported operation %s", rc.Operation.Operation)
+	}
+	if err != nil {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", err))
+	}
+
+	b, err := json.Marshal(map[string]interface{}{root.Alias: toResponseJSON(data)})
+	if err != nil {
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", err))
+	}
+	return graphql.OneShot(&graphql.Response{Data: b})
+}
+
+func (e *executableSchema) resolveQuery(ctx context.Context, rc *graphql.OperationContext, field *ast.Field) (interface{}, error) {
+	if field.Name != "conditionPreview" {
+		return nil, fmt.Errorf("unknown field %q", field.Name)
+	}
+	dashboardID, err := intArg(field.Arguments, "dashboardId", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	panelID, err := intArg(field.Arguments, "panelId", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	return e.resolvers.Query().ConditionPreview(ctx, dashboardID, panelID)
+}
+
+func (e *executableSchema) resolveMutation(ctx context.Context, rc *graphql.OperationContext, field *ast.Field) (interface{}, error) {
+	if field.Name != "evaluateCondition" {
+		return nil, fmt.Errorf("unknown field %q", field.Name)
+	}
+	dashboardID, err := intArg(field.Arguments, "dashboardId", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	panelID, err := intArg(field.Arguments, "panelId", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	refID, err := stringArg(field.Arguments, "refId", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	from, err := stringArg(field.Arguments, "from", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	to, err := stringArg(field.Arguments, "to", rc.Variables)
+	if err != nil {
+		return nil, err
+	}
+	return e.resolvers.Mutation().EvaluateCondition(ctx, dashboardID, panelID, refID, from, to)
+}
+
+// rootField returns the operation's first top-level field selection only;
+// see the package doc comment for why additional root fields aren't
+// resolved.
+func rootField(op *ast.OperationDefinition) *ast.Field {
+	for _, sel := range op.SelectionSet {
+		if f, ok := sel.(*ast.Field); ok {
+			return f
+		}
+	}
+	return nil
+}
+
+func rootTypeName(op ast.Operation) string {
+	if op == ast.Mutation {
+		return "Mutation"
+	}
+	return "Query"
+}
+
+// intArg and stringArg resolve an argument's value the way a real
+// gqlgen-generated unmarshaler does: through ast.Value.Value(vars), which
+// substitutes bound GraphQL variables before returning the literal. Reading
+// arg.Value.Raw directly (the previous implementation) only works for
+// inline literals — it returns the variable's name, not its value, for
+// `field(arg: $var)`.
+func intArg(args ast.ArgumentList, name string, vars map[string]interface{}) (int64, error) {
+	arg := args.ForName(name)
+	if arg == nil {
+		return 0, nil
+	}
+	v, err := arg.Value.Value(vars)
+	if err != nil {
+		return 0, fmt.Errorf("argument %q: %w", name, err)
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case float64:
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("argument %q: unsupported value %v (%T)", name, v, v)
+	}
+}
+
+func stringArg(args ast.ArgumentList, name string, vars map[string]interface{}) (string, error) {
+	arg := args.ForName(name)
+	if arg == nil {
+		return "", nil
+	}
+	v, err := arg.Value.Value(vars)
+	if err != nil {
+		return "", fmt.Errorf("argument %q: %w", name, err)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("argument %q: expected string, got %T", name, v)
+	}
+	return s, nil
+}
+
+// toResponseJSON converts a resolver's return value into the shape
+// encoding/json should serialize, routing State fields through
+// model.MarshalState so enum values come out upper-cased (e.g. "NODATA")
+// the way the schema declares them, instead of through State's own
+// MarshalJSON (which produces the dashboard-model casing, e.g. "NoData").
+func toResponseJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case *model.Condition:
+		return conditionJSON(val)
+	case *model.ExecutionResults:
+		return executionResultsJSON(val)
+	default:
+		return val
+	}
+}
+
+func conditionJSON(c *model.Condition) map[string]interface{} {
+	queries := make([]interface{}, 0, len(c.QueriesAndExpressions))
+	for _, q := range c.QueriesAndExpressions {
+		queries = append(queries, map[string]interface{}{
+			"refId":         q.RefID,
+			"queryType":     q.QueryType,
+			"maxDataPoints": q.MaxDataPoints,
+			"intervalMs":    q.IntervalMs,
+			"datasourceId":  q.DatasourceID,
+			"model":         q.Model,
+		})
+	}
+	return map[string]interface{}{
+		"refId":                 c.RefID,
+		"queriesAndExpressions": queries,
+	}
+}
+
+func resultJSON(r *model.Result) map[string]interface{} {
+	return map[string]interface{}{
+		"instance": r.Instance,
+		"state":    stateJSON(r.State),
+	}
+}
+
+func dataFrameJSON(f *model.DataFrame) map[string]interface{} {
+	return map[string]interface{}{
+		"name": f.Name,
+		"json": f.JSON,
+	}
+}
+
+func executionResultsJSON(r *model.ExecutionResults) map[string]interface{} {
+	results := make([]interface{}, 0, len(r.Results))
+	for _, f := range r.Results {
+		results = append(results, dataFrameJSON(f))
+	}
+	instances := make([]interface{}, 0, len(r.Instances))
+	for _, res := range r.Instances {
+		instances = append(instances, resultJSON(res))
+	}
+	return map[string]interface{}{
+		"alertDefinitionId": r.AlertDefinitionID,
+		"error":             r.Error,
+		"results":           results,
+		"instances":         instances,
+	}
+}
+
+// stateJSON marshals a State through model.MarshalState so its enum name
+// matches the casing the schema declares (e.g. "NODATA", not "NoData").
+func stateJSON(s model.State) json.RawMessage {
+	var buf bytes.Buffer
+	model.MarshalState(s).MarshalGQL(&buf)
+	return json.RawMessage(buf.Bytes())
+}
+
+// introspectSchema, introspectType and their helpers implement the
+// `__schema`/`__type` root fields directly off the parsed ast.Schema, so
+// GraphQL tooling that starts with an introspection query (the Playground's
+// schema explorer, graphql-codegen, Apollo) gets a real schema description
+// instead of an "unknown field" error. They return the full description
+// regardless of the client's requested selection set; well-behaved GraphQL
+// clients ignore response fields they didn't ask for, so this is compatible
+// in practice even though it isn't spec-minimal.
+func introspectSchema(s *ast.Schema) map[string]interface{} {
+	return map[string]interface{}{
+		"queryType":        typeRef(s.Query),
+		"mutationType":     typeRef(s.Mutation),
+		"subscriptionType": typeRef(s.Subscription),
+		"types":            introspectTypes(s),
+		"directives":       []interface{}{},
+	}
+}
+
+func typeRef(def *ast.Definition) interface{} {
+	if def == nil {
+		return nil
+	}
+	return map[string]interface{}{"name": def.Name}
+}
+
+func introspectTypes(s *ast.Schema) []interface{} {
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	types := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		types = append(types, introspectType(s, s.Types[name]))
+	}
+	return types
+}
+
+func introspectType(s *ast.Schema, def *ast.Definition) map[string]interface{} {
+	if def == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"kind":        string(def.Kind),
+		"name":        def.Name,
+		"description": def.Description,
+		"fields":      introspectFields(s, def.Fields),
+		"enumValues":  introspectEnumValues(def.EnumValues),
+		"interfaces":  []interface{}{},
+	}
+}
+
+func introspectFields(s *ast.Schema, fields ast.FieldList) []interface{} {
+	out := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		if strings.HasPrefix(f.Name, "__") {
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"name":        f.Name,
+			"description": f.Description,
+			"args":        introspectArgs(s, f.Arguments),
+			"type":        introspectTypeRef(s, f.Type),
+		})
+	}
+	return out
+}
+
+func introspectArgs(s *ast.Schema, args ast.ArgumentDefinitionList) []interface{} {
+	out := make([]interface{}, 0, len(args))
+	for _, a := range args {
+		out = append(out, map[string]interface{}{
+			"name": a.Name,
+			"type": introspectTypeRef(s, a.Type),
+		})
+	}
+	return out
+}
+
+func introspectEnumValues(values ast.EnumValueList) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		out = append(out, map[string]interface{}{"name": v.Name})
+	}
+	return out
+}
+
+func introspectTypeRef(s *ast.Schema, t *ast.Type) map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+	if t.NonNull {
+		inner := *t
+		inner.NonNull = false
+		return map[string]interface{}{
+			"kind":   "NON_NULL",
+			"name":   nil,
+			"ofType": introspectTypeRef(s, &inner),
+		}
+	}
+	if t.Elem != nil {
+		return map[string]interface{}{
+			"kind":   "LIST",
+			"name":   nil,
+			"ofType": introspectTypeRef(s, t.Elem),
+		}
+	}
+
+	kind := "SCALAR"
+	if def := s.Types[t.NamedType]; def != nil {
+		kind = string(def.Kind)
+	}
+	return map[string]interface{}{
+		"kind": kind,
+		"name": t.NamedType,
+	}
+}
+
+const schemaSource = `
+scalar Int64
+
+enum State {
+  NORMAL
+  ALERTING
+  PENDING
+  NODATA
+  ERROR
+}
+
+type AlertQuery {
+  refId: String!
+  queryType: String!
+  maxDataPoints: Int64!
+  intervalMs: Int64!
+  datasourceId: Int64!
+  model: String!
+}
+
+type Condition {
+  refId: String!
+  queriesAndExpressions: [AlertQuery!]!
+}
+
+type DataFrame {
+  name: String!
+  json: String!
+}
+
+type ExecutionResults {
+  alertDefinitionId: Int64!
+  error: String
+  results: [DataFrame!]!
+  instances: [Result!]!
+}
+
+type Result {
+  instance: String!
+  state: State!
+}
+
+type Query {
+  conditionPreview(dashboardId: Int64!, panelId: Int64!): Condition!
+}
+
+type Mutation {
+  evaluateCondition(dashboardId: Int64!, panelId: Int64!, refId: String!, from: String!, to: String!): ExecutionResults!
+}
+`
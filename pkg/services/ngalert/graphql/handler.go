@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/playground"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/graphql/gqlexec"
+)
+
+// NewHandler builds the http.Handler that serves the ngalert GraphQL API,
+// wired to the given AlertNG service.
+func NewHandler(alertNG *eval.AlertNG) http.Handler {
+	resolver := NewResolver(alertNG)
+	return handler.NewDefaultServer(gqlexec.NewExecutableSchema(gqlexec.Config{
+		Resolvers: resolver,
+	}))
+}
+
+// NewPlaygroundHandler serves the GraphQL playground UI at the given
+// endpoint, pointed at the GraphQL API endpoint.
+func NewPlaygroundHandler(endpoint string) http.Handler {
+	return playground.Handler("NGAlert GraphQL", endpoint)
+}
+
+// WithSignedInUser stores the request's SignedInUser (populated by the
+// existing auth middleware) in ctx so resolvers can recover it via
+// signedInUserFromContext.
+func WithSignedInUser(ctx context.Context, signedInUser *models.SignedInUser) context.Context {
+	return context.WithValue(ctx, signedInUserCtxKey{}, signedInUser)
+}
@@ -0,0 +1,79 @@
+// Package model holds the GraphQL-facing DTOs for the ngalert GraphQL API.
+// It is hand-written, mirroring what `go run github.com/99designs/gqlgen
+// generate` would produce from gqlgen.yml's "model" section for this
+// schema — see ../gqlexec's package doc comment for why codegen isn't
+// wired up yet.
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// AlertQuery mirrors tsdb.Query, the backend-expression/datasource query that
+// makes up part of a Condition.
+type AlertQuery struct {
+	RefID         string `json:"refId"`
+	QueryType     string `json:"queryType"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+	IntervalMs    int64  `json:"intervalMs"`
+	DatasourceID  int64  `json:"datasourceId"`
+	Model         string `json:"model"`
+}
+
+// Condition mirrors eval.Condition.
+type Condition struct {
+	RefID                 string        `json:"refId"`
+	QueriesAndExpressions []*AlertQuery `json:"queriesAndExpressions"`
+}
+
+// DataFrame is a JSON-encoded data.Frame.
+type DataFrame struct {
+	Name string `json:"name"`
+	JSON string `json:"json"`
+}
+
+// ExecutionResults mirrors eval.ExecutionResults, with Instances holding
+// the per-alert-instance states eval.EvaluateExecutionResult derives from
+// Results so a caller gets both in one response.
+type ExecutionResults struct {
+	AlertDefinitionID int64        `json:"alertDefinitionId"`
+	Error             *string      `json:"error"`
+	Results           []*DataFrame `json:"results"`
+	Instances         []*Result    `json:"instances"`
+}
+
+// Result mirrors eval.Result.
+type Result struct {
+	Instance string `json:"instance"`
+	State    State  `json:"state"`
+}
+
+// State is the gqlgen-bound representation of eval.State, mapped via
+// gqlgen.yml's "models" section to the type the eval package already defines.
+type State = eval.State
+
+// MarshalState writes a State as its GraphQL enum name, e.g. "NODATA".
+func MarshalState(s State) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(strings.ToUpper(s.String())))
+	})
+}
+
+// UnmarshalState parses a GraphQL enum name into a State.
+func UnmarshalState(v interface{}) (State, error) {
+	name, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("enum %T is not a string", v)
+	}
+	s, err := eval.StateFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	return s, nil
+}
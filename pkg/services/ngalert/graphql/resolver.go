@@ -0,0 +1,37 @@
+// Package graphql exposes alert condition loading and evaluation
+// (pkg/services/ngalert/eval) over a GraphQL API, so UI clients can
+// introspect and run alert evaluations without going through the classic
+// REST dashboard-scraping code path. The executable schema in ./gqlexec is
+// a bespoke hand-written dispatcher, not gqlgen output; see its package
+// doc comment for the gaps that distinguish it from the real thing.
+package graphql
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// Resolver is the root GraphQL resolver. It holds the dependencies the
+// query/mutation resolvers need to load and execute alert conditions.
+type Resolver struct {
+	AlertNG *eval.AlertNG
+}
+
+// NewResolver returns a Resolver wired to the given AlertNG service.
+func NewResolver(alertNG *eval.AlertNG) *Resolver {
+	return &Resolver{AlertNG: alertNG}
+}
+
+// signedInUserFromContext recovers the SignedInUser that WithSignedInUser
+// stored in the request context; the ngalert HTTP route copies it there
+// from the ReqContext the existing auth middleware populated.
+func signedInUserFromContext(ctx context.Context) *models.SignedInUser {
+	u, _ := ctx.Value(signedInUserCtxKey{}).(*models.SignedInUser)
+	return u
+}
+
+// signedInUserCtxKey is the context key the HTTP handler stores the
+// request's SignedInUser under before invoking the GraphQL executor.
+type signedInUserCtxKey struct{}
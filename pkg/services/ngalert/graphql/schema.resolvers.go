@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/graphql/gqlexec"
+	"github.com/grafana/grafana/pkg/services/ngalert/graphql/model"
+)
+
+// ConditionPreview is the resolver for the conditionPreview field.
+func (r *Resolver) ConditionPreview(ctx context.Context, dashboardID int64, panelID int64) (*model.Condition, error) {
+	condition, err := r.AlertNG.LoadAlertCondition(dashboardID, panelID, "", signedInUserFromContext(ctx), false)
+	if err != nil {
+		return nil, err
+	}
+	return toModelCondition(condition), nil
+}
+
+// EvaluateCondition is the resolver for the evaluateCondition field. It
+// returns ExecutionResults rather than a bare list of instance states so a
+// caller can see partial results (the raw query frames, or the execution
+// error) even when evaluation didn't fully succeed, instead of the whole
+// mutation failing with no information.
+func (r *Resolver) EvaluateCondition(ctx context.Context, dashboardID int64, panelID int64, refID string, from string, to string) (*model.ExecutionResults, error) {
+	condition, err := r.AlertNG.LoadAlertCondition(dashboardID, panelID, refID, signedInUserFromContext(ctx), false)
+	if err != nil {
+		return nil, err
+	}
+
+	execResults, err := condition.Execute(eval.AlertExecCtx{
+		SignedInUser: signedInUserFromContext(ctx),
+		Ctx:          ctx,
+	}, from, to)
+	if execResults == nil {
+		return nil, err
+	}
+
+	modelExecResults := toModelExecutionResults(execResults)
+	if err != nil {
+		return modelExecResults, nil
+	}
+
+	results, err := eval.EvaluateExecutionResult(execResults)
+	if err != nil {
+		msg := err.Error()
+		modelExecResults.Error = &msg
+		return modelExecResults, nil
+	}
+	for _, res := range results {
+		modelExecResults.Instances = append(modelExecResults.Instances, &model.Result{
+			Instance: res.Instance.String(),
+			State:    res.State,
+		})
+	}
+	return modelExecResults, nil
+}
+
+func toModelExecutionResults(execResults *eval.ExecutionResults) *model.ExecutionResults {
+	out := &model.ExecutionResults{
+		AlertDefinitionID: execResults.AlertDefinitionID,
+	}
+	if execResults.Error != nil {
+		msg := execResults.Error.Error()
+		out.Error = &msg
+	}
+	for _, f := range execResults.Results {
+		frameJSON, err := json.Marshal(f)
+		if err != nil {
+			continue
+		}
+		out.Results = append(out.Results, &model.DataFrame{
+			Name: f.Name,
+			JSON: string(frameJSON),
+		})
+	}
+	return out
+}
+
+func toModelCondition(condition *eval.Condition) *model.Condition {
+	queries := make([]*model.AlertQuery, 0, len(condition.QueriesAndExpressions))
+	for _, q := range condition.QueriesAndExpressions {
+		var modelJSON string
+		if q.Model != nil {
+			if b, err := q.Model.MarshalJSON(); err == nil {
+				modelJSON = string(b)
+			}
+		}
+		var datasourceID int64
+		if q.DataSource != nil {
+			datasourceID = q.DataSource.Id
+		}
+		queries = append(queries, &model.AlertQuery{
+			RefID:         q.RefId,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMs:    q.IntervalMs,
+			DatasourceID:  datasourceID,
+			Model:         modelJSON,
+		})
+	}
+	return &model.Condition{
+		RefID:                 condition.RefID,
+		QueriesAndExpressions: queries,
+	}
+}
+
+// Mutation returns gqlexec.MutationResolver implementation.
+func (r *Resolver) Mutation() gqlexec.MutationResolver { return r }
+
+// Query returns gqlexec.QueryResolver implementation.
+func (r *Resolver) Query() gqlexec.QueryResolver { return r }
@@ -0,0 +1,52 @@
+package eval
+
+import (
+	"net/http"
+
+	macaron "gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ngGraphQL "github.com/grafana/grafana/pkg/services/ngalert/graphql"
+)
+
+// graphQLBasePath is where the ngalert GraphQL API and its Playground UI
+// (pkg/services/ngalert/graphql) are served.
+const graphQLBasePath = "/api/alert-definitions/graphql"
+
+// registerGraphQLRoutes wires the handlers built in the graphql subpackage
+// into the HTTP server via the injected RouteRegister, so they're actually
+// reachable rather than constructible-but-unused helpers. The whole group
+// sits behind middleware.ReqSignedIn, the same gate every other dashboard-
+// and datasource-touching REST route in pkg/api uses: evaluateCondition and
+// conditionPreview both let the caller run arbitrary datasource queries by
+// dashboardId/panelId, so this must never be reachable anonymously.
+func (ng *AlertNG) registerGraphQLRoutes() {
+	if ng.RouteRegister == nil {
+		// Unset in unit tests that construct an AlertNG directly instead of
+		// going through the DI container.
+		return
+	}
+
+	apiHandler := ngGraphQL.NewHandler(ng)
+	playgroundHandler := ngGraphQL.NewPlaygroundHandler(graphQLBasePath)
+
+	ng.RouteRegister.Group(graphQLBasePath, func(group routing.RouteRegister) {
+		group.Post("/", ng.serveHTTP(apiHandler, true))
+		group.Get("/playground", ng.serveHTTP(playgroundHandler, false))
+	}, middleware.ReqSignedIn)
+}
+
+// serveHTTP adapts an http.Handler into a macaron.Handler, propagating the
+// request's SignedInUser into the context resolvers read it from when
+// withSignedInUser is set (the Playground UI itself needs no user).
+func (ng *AlertNG) serveHTTP(h http.Handler, withSignedInUser bool) macaron.Handler {
+	return func(c *models.ReqContext) {
+		req := c.Req.Request
+		if withSignedInUser {
+			req = req.WithContext(ngGraphQL.WithSignedInUser(req.Context(), c.SignedInUser))
+		}
+		h.ServeHTTP(c.Resp, req)
+	}
+}
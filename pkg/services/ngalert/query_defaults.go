@@ -0,0 +1,137 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// queryFieldDefault is one entry of a queryDefaults table: either a value to
+// fall back to when the query JSON omits the field, or (when Value is nil)
+// a marker that the field is required and must be present after all
+// defaults have been merged.
+type queryFieldDefault struct {
+	Value    interface{} `json:"value,omitempty"`
+	Required bool        `json:"required,omitempty"`
+}
+
+// queryDefaults maps a query field name (e.g. "maxDataPoints", "step",
+// "region") to its default/requiredness.
+type queryDefaults map[string]queryFieldDefault
+
+// allDatasourceTypes is the queryDefaultsTable key for defaults that apply
+// to every query regardless of datasource type, e.g. "refId" and "orgId".
+const allDatasourceTypes = ""
+
+// defaultQueryDefaultsDescriptor is the default location of the JSON
+// descriptor consulted at AlertNG.Init, alongside the built-in table.
+// Deployments without this file fall back to builtinQueryDefaults alone.
+const defaultQueryDefaultsDescriptor = "conf/alerting_query_defaults.json"
+
+// builtinQueryDefaults preserves the fallbacks LoadAlertCondition used to
+// hard-code: maxDataPoints=100, intervalMs=1000, orgId=0 and refId="A".
+var builtinQueryDefaults = map[string]queryDefaults{
+	allDatasourceTypes: {
+		"refId":         {Value: "A"},
+		"orgId":         {Value: int64(0)},
+		"maxDataPoints": {Value: int64(100)},
+		"intervalMs":    {Value: int64(1000)},
+	},
+}
+
+// queryDefaultsTable is the per-datasource-type table of query field
+// defaults consulted by LoadAlertCondition. It is safe for concurrent use:
+// plugins may call AlertNG.RegisterQueryDefaults after Init has returned.
+type queryDefaultsTable struct {
+	mu     sync.RWMutex
+	byType map[string]queryDefaults
+}
+
+func newQueryDefaultsTable(seed map[string]queryDefaults) *queryDefaultsTable {
+	t := &queryDefaultsTable{byType: map[string]queryDefaults{}}
+	for datasourceType, defaults := range seed {
+		for field, def := range defaults {
+			t.set(datasourceType, field, def)
+		}
+	}
+	return t
+}
+
+func (t *queryDefaultsTable) set(datasourceType, field string, def queryFieldDefault) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	table := t.byType[datasourceType]
+	if table == nil {
+		table = queryDefaults{}
+		t.byType[datasourceType] = table
+	}
+	table[field] = def
+}
+
+// loadDescriptor merges a JSON descriptor of the form
+// {"<datasourceType>": {"<field>": {"value": ..., "required": true}}}
+// into the table. A missing file is not an error: the built-in defaults
+// still apply.
+func (t *queryDefaultsTable) loadDescriptor(path string) error {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read query defaults descriptor %q: %w", path, err)
+	}
+
+	var descriptor map[string]queryDefaults
+	if err := json.Unmarshal(blob, &descriptor); err != nil {
+		return fmt.Errorf("failed to parse query defaults descriptor %q: %w", path, err)
+	}
+	for datasourceType, defaults := range descriptor {
+		for field, def := range defaults {
+			t.set(datasourceType, field, def)
+		}
+	}
+	return nil
+}
+
+// register merges plugin-provided default values for a datasource type's
+// query fields, as exposed by AlertNG.RegisterQueryDefaults.
+func (t *queryDefaultsTable) register(datasourceType string, defaults map[string]interface{}) {
+	for field, value := range defaults {
+		t.set(datasourceType, field, queryFieldDefault{Value: value})
+	}
+}
+
+// merge fills in any field of query that is absent, consulting the
+// datasource-type-specific defaults first and falling back to the defaults
+// that apply to every datasource type. It returns a validation error if a
+// field is marked required and, after merging, still has no value.
+func (t *queryDefaultsTable) merge(query *simplejson.Json, datasourceType string) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var required []string
+	for _, table := range []queryDefaults{t.byType[datasourceType], t.byType[allDatasourceTypes]} {
+		for field, def := range table {
+			if _, present := query.CheckGet(field); present {
+				continue
+			}
+			if def.Value != nil {
+				query.Set(field, def.Value)
+				continue
+			}
+			if def.Required {
+				required = append(required, field)
+			}
+		}
+	}
+	for _, field := range required {
+		if _, present := query.CheckGet(field); !present {
+			return fmt.Errorf("query field %q is required for datasource type %q but has no value and no registered default", field, datasourceType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestQueryDefaultsTable_Merge_RequiredWithoutDefault(t *testing.T) {
+	table := newQueryDefaultsTable(nil)
+	table.set(allDatasourceTypes, "region", queryFieldDefault{Required: true})
+
+	query := simplejson.New()
+	if err := table.merge(query, "prometheus"); err == nil {
+		t.Fatal("expected an error for a required field with no value and no default")
+	}
+}
+
+func TestQueryDefaultsTable_Merge_RequiredAlreadyPresent(t *testing.T) {
+	table := newQueryDefaultsTable(nil)
+	table.set(allDatasourceTypes, "region", queryFieldDefault{Required: true})
+
+	query := simplejson.New()
+	query.Set("region", "us-east-1")
+	if err := table.merge(query, "cloudwatch"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestQueryDefaultsTable_Merge_TypeOverridesGlobal(t *testing.T) {
+	table := newQueryDefaultsTable(nil)
+	table.set(allDatasourceTypes, "maxDataPoints", queryFieldDefault{Value: int64(100)})
+	table.set("prometheus", "maxDataPoints", queryFieldDefault{Value: int64(1000)})
+
+	query := simplejson.New()
+	if err := table.merge(query, "prometheus"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := query.Get("maxDataPoints").MustInt64(); got != 1000 {
+		t.Errorf("expected type-specific default 1000, got %d", got)
+	}
+}
+
+func TestQueryDefaultsTable_Merge_GlobalAppliesWithoutOverride(t *testing.T) {
+	table := newQueryDefaultsTable(nil)
+	table.set(allDatasourceTypes, "maxDataPoints", queryFieldDefault{Value: int64(100)})
+	table.set("prometheus", "maxDataPoints", queryFieldDefault{Value: int64(1000)})
+
+	query := simplejson.New()
+	if err := table.merge(query, "graphite"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := query.Get("maxDataPoints").MustInt64(); got != 100 {
+		t.Errorf("expected global default 100, got %d", got)
+	}
+}
+
+func TestQueryDefaultsTable_Register(t *testing.T) {
+	table := newQueryDefaultsTable(nil)
+	table.register("elasticsearch", map[string]interface{}{"timeField": "@timestamp"})
+
+	query := simplejson.New()
+	if err := table.merge(query, "elasticsearch"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := query.Get("timeField").MustString(); got != "@timestamp" {
+		t.Errorf("expected registered default %q, got %q", "@timestamp", got)
+	}
+}
@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Reducer collapses a data.Frame representing a single alert instance's
+// time series down to one float64 value, and decides the State that value
+// implies (e.g. NoData when the frame has no samples to reduce).
+type Reducer interface {
+	Reduce(frame *data.Frame) (float64, State, error)
+}
+
+// reducers are the built-in Reducer implementations, looked up by the name
+// a dashboard panel's alert condition stores in its reducer's "type" field.
+var reducers = map[string]Reducer{
+	"last":   aggregateReducer(lastOf),
+	"avg":    aggregateReducer(avgOf),
+	"min":    aggregateReducer(minOf),
+	"max":    aggregateReducer(maxOf),
+	"sum":    aggregateReducer(sumOf),
+	"count":  aggregateReducer(countOf),
+	"median": aggregateReducer(medianOf),
+}
+
+// GetReducer looks up a built-in Reducer by name, as selected by
+// Condition.Reducer, so Execute can fail fast on an unknown reducer name
+// instead of silently defaulting. LoadAlertCondition stores the name as the
+// panel gives it and does not look it up.
+func GetReducer(name string) (Reducer, error) {
+	r, ok := reducers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown reducer %q", name)
+	}
+	return r, nil
+}
+
+// aggregateReducer adapts a plain values-to-float aggregate function into a
+// Reducer: it extracts the frame's float64 value field, skips null
+// samples, aggregates what remains, and maps the result to Alerting/Normal
+// the same way the original single-value evaluator did.
+type aggregateReducer func(values []float64) float64
+
+func (fn aggregateReducer) Reduce(frame *data.Frame) (float64, State, error) {
+	field, err := valueField(frame)
+	if err != nil {
+		return 0, Error, err
+	}
+
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return 0, Error, fmt.Errorf("unable to get frame row length: %w", err)
+	}
+
+	values := make([]float64, 0, rowLen)
+	for i := 0; i < rowLen; i++ {
+		// FloatAt reports a nil sample as NaN rather than an error, so read
+		// the *float64 directly to tell nulls apart.
+		v, _ := field.At(i).(*float64)
+		if v == nil {
+			continue // null sample
+		}
+		values = append(values, *v)
+	}
+	if len(values) == 0 {
+		return 0, NoData, nil
+	}
+
+	reduced := fn(values)
+	state := Normal
+	if reduced != 0 {
+		state = Alerting
+	}
+	return reduced, state, nil
+}
+
+// valueField returns the frame's single nullable float64 field, which holds
+// the instance's time-series values (and its Labels identify the instance).
+// Any time field alongside it is ignored.
+func valueField(f *data.Frame) (*data.Field, error) {
+	var field *data.Field
+	for _, candidate := range f.Fields {
+		if candidate.Type() != data.FieldTypeNullableFloat64 {
+			continue
+		}
+		if field != nil {
+			return nil, fmt.Errorf("invalid frame %v: more than one nullable float64 field", f.Name)
+		}
+		field = candidate
+	}
+	if field == nil {
+		return nil, fmt.Errorf("invalid frame %v: no nullable float64 field", f.Name)
+	}
+	return field, nil
+}
+
+func lastOf(values []float64) float64 {
+	return values[len(values)-1]
+}
+
+func avgOf(values []float64) float64 {
+	return sumOf(values) / float64(len(values))
+}
+
+func minOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func maxOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func sumOf(values []float64) float64 {
+	var s float64
+	for _, v := range values {
+		s += v
+	}
+	return s
+}
+
+func countOf(values []float64) float64 {
+	return float64(len(values))
+}
+
+func medianOf(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
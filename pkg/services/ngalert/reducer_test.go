@@ -0,0 +1,129 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func valueFrame(values ...*float64) *data.Frame {
+	return data.NewFrame("",
+		data.NewField("time", nil, make([]int64, len(values))),
+		data.NewField("value", nil, values),
+	)
+}
+
+func TestGetReducer(t *testing.T) {
+	for _, name := range []string{"last", "avg", "min", "max", "sum", "count", "median"} {
+		if _, err := GetReducer(name); err != nil {
+			t.Errorf("GetReducer(%q) returned unexpected error: %s", name, err)
+		}
+	}
+
+	if _, err := GetReducer("bogus"); err == nil {
+		t.Error("GetReducer(\"bogus\") expected an error, got nil")
+	}
+}
+
+func TestAggregateReducer_NoData(t *testing.T) {
+	cases := []struct {
+		name  string
+		frame *data.Frame
+	}{
+		{"no rows", valueFrame()},
+		{"all null", valueFrame(nil, nil, nil)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reducer, _ := GetReducer("last")
+			_, state, err := reducer.Reduce(tc.frame)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if state != NoData {
+				t.Errorf("expected NoData, got %s", state)
+			}
+		})
+	}
+}
+
+func TestAggregateReducer_Values(t *testing.T) {
+	frame := valueFrame(floatPtr(1), nil, floatPtr(2), floatPtr(3))
+
+	cases := []struct {
+		reducer  string
+		expected float64
+	}{
+		{"last", 3},
+		{"avg", 2},
+		{"min", 1},
+		{"max", 3},
+		{"sum", 6},
+		{"count", 3},
+		{"median", 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.reducer, func(t *testing.T) {
+			reducer, err := GetReducer(tc.reducer)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			value, state, err := reducer.Reduce(frame)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if value != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, value)
+			}
+			expectedState := Alerting
+			if value == 0 {
+				expectedState = Normal
+			}
+			if state != expectedState {
+				t.Errorf("expected state %s, got %s", expectedState, state)
+			}
+		})
+	}
+}
+
+func TestAggregateReducer_StateFollowsValue(t *testing.T) {
+	reducer, _ := GetReducer("sum")
+
+	_, state, err := reducer.Reduce(valueFrame(floatPtr(0), floatPtr(0)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state != Normal {
+		t.Errorf("expected Normal for zero-valued reduction, got %s", state)
+	}
+
+	_, state, err = reducer.Reduce(valueFrame(floatPtr(1)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state != Alerting {
+		t.Errorf("expected Alerting for non-zero reduction, got %s", state)
+	}
+}
+
+func TestValueField_MultipleFloatFields(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("a", nil, []*float64{floatPtr(1)}),
+		data.NewField("b", nil, []*float64{floatPtr(2)}),
+	)
+
+	reducer, _ := GetReducer("last")
+	_, state, err := reducer.Reduce(frame)
+	if err == nil {
+		t.Fatal("expected an error for a frame with more than one nullable float64 field")
+	}
+	if state != Error {
+		t.Errorf("expected Error state, got %s", state)
+	}
+}